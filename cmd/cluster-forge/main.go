@@ -0,0 +1,58 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+// Command cluster-forge runs utils.CreateCrossplaneObject to turn a
+// working/<name>/ directory of rendered Kubernetes manifests into the
+// object/crd/secret output files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dzautner/cluster-forge/cmd/utils"
+)
+
+func main() {
+	name := flag.String("name", "", "package name; manifests are read from working/<name>/ and output files are named <name>-*.yaml (required)")
+	environment := flag.String("environment", "", "environment to render values for, see Config.Environments (defaults to \"default\")")
+	workingDir := flag.String("working-dir", "", "root manifests are read from (defaults to \"working\")")
+	outputDir := flag.String("output-dir", "", "root object/crd/secret files are written to (defaults to \"output\")")
+	flag.Parse()
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "cluster-forge: -name is required")
+		os.Exit(1)
+	}
+
+	// Config.Environments itself isn't populated from flags: this snapshot
+	// has no config-file loader yet, so -environment only has an effect
+	// once a caller builds Config.Environments some other way (e.g. by
+	// embedding this command in a larger program).
+	config := utils.Config{
+		Name:        *name,
+		Environment: *environment,
+		WorkingDir:  *workingDir,
+		OutputDir:   *outputDir,
+		Progress:    utils.NewTextProgressWriter(os.Stdout),
+	}
+
+	if err := utils.CreateCrossplaneObject(config); err != nil {
+		fmt.Fprintf(os.Stderr, "cluster-forge: %v\n", err)
+		os.Exit(1)
+	}
+}
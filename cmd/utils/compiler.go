@@ -21,11 +21,13 @@ import (
 	"embed"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 )
 
 //go:embed templates/*
@@ -48,16 +50,20 @@ type platformpackage struct {
 	Name    string
 	Kind    string
 	Content bytes.Buffer
+	// Values is the merged values tree for the active Environment,
+	// exposed to template.templ, header.templ and footer.templ as
+	// .Values.
+	Values map[string]interface{}
 }
 
-func shouldSkipFile(file os.DirEntry, dirPath string) bool {
+func shouldSkipFile(fsys FS, file fs.DirEntry, dirPath string) bool {
 	// Skip directories
 	if file.IsDir() {
 		return true
 	}
 	name := file.Name()
 	// Check if file contains helm.sh/hook
-	content, err := os.ReadFile(dirPath + "/" + name)
+	content, err := fsys.ReadFile(dirPath + "/" + name)
 	if err != nil {
 		log.Printf("Error reading file %s: %v", name, err)
 		return true
@@ -69,30 +75,60 @@ func shouldSkipFile(file os.DirEntry, dirPath string) bool {
 	return false
 }
 
-// CreateCrossplaneObject reads the output of the SplitYAML function and writes it to a file
-func CreateCrossplaneObject(config Config) {
+// CreateCrossplaneObject reads the output of the SplitYAML function and
+// writes it to a file. Progress is reported through config.Progress as it
+// goes; the caller decides whether a returned error is fatal.
+func CreateCrossplaneObject(config Config) error {
+	progress := config.progress()
+	started := time.Now()
+
 	// read a command line argument and assign it to a variable
 	platformpackage := new(platformpackage)
 	platformpackage.Name = config.Name
-	objectFile, err := os.OpenFile("output/"+platformpackage.Name+"-object.yaml", os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	progress.PackageStarted(PackageStartedEvent{Name: platformpackage.Name})
+
+	fail := func(err error) error {
+		progress.Error(ErrorEvent{Err: err})
+		return err
+	}
+
+	fsys := config.fs()
+	if env, ok := config.ActiveEnvironment(); ok {
+		values, err := env.LoadValues(fsys)
+		if err != nil {
+			return fail(err)
+		}
+		platformpackage.Values = values
+	}
+	workingDir := config.workingDir() + "/" + platformpackage.Name
+	outputDir := config.outputDir()
+
+	objectFile, err := fsys.Create(outputDir + "/" + platformpackage.Name + "-object.yaml")
 	if err != nil {
-		log.Fatalln(err)
+		return fail(err)
 	}
-	defer objectFile.Close()
-	crdFile, err := os.OpenFile("output/"+platformpackage.Name+"-crd.yaml", os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	crdFile, err := fsys.Create(outputDir + "/" + platformpackage.Name + "-crd.yaml")
 	if err != nil {
-		log.Fatalln(err)
+		objectFile.Close()
+		return fail(err)
 	}
-	defer crdFile.Close()
-	secretFile, err := os.OpenFile("output/"+platformpackage.Name+"-secret.yaml", os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	secretFile, err := fsys.Create(outputDir + "/" + platformpackage.Name + "-secret.yaml")
 	if err != nil {
-		log.Fatalln(err)
+		objectFile.Close()
+		crdFile.Close()
+		return fail(err)
 	}
-	defer secretFile.Close()
 
-	files, _ := os.ReadDir("working/" + platformpackage.Name)
+	closeOutputs := func() {
+		objectFile.Close()
+		crdFile.Close()
+		secretFile.Close()
+	}
+
+	seenTargets := map[string]bool{}
+	files, _ := fsys.ReadDir(workingDir)
 	for _, file := range files {
-		if shouldSkipFile(file, "working/"+platformpackage.Name) {
+		if shouldSkipFile(fsys, file, workingDir) {
 			continue
 		}
 		// split the file name to get the kind
@@ -100,9 +136,26 @@ func CreateCrossplaneObject(config Config) {
 		// strip the .yaml extension
 		platformpackage.Kind = strings.TrimSuffix(platformpackage.Kind, ".yaml")
 		// Read the content of the file
-		content, err := os.ReadFile("working/" + platformpackage.Name + "/" + file.Name())
+		content, err := fsys.ReadFile(workingDir + "/" + file.Name())
 		if err != nil {
-			log.Fatalln(err)
+			closeOutputs()
+			return fail(err)
+		}
+		// *.yaml.gotmpl manifests are executed with .Values from the
+		// active Environment; every other file is treated as plain
+		// YAML with no template execution.
+		if strings.HasSuffix(file.Name(), ".gotmpl") {
+			manifestTemp, err := template.New(file.Name()).Parse(string(content))
+			if err != nil {
+				closeOutputs()
+				return fail(err)
+			}
+			var rendered bytes.Buffer
+			if err = manifestTemp.Execute(&rendered, platformpackage); err != nil {
+				closeOutputs()
+				return fail(err)
+			}
+			content = rendered.Bytes()
 		}
 		lines := strings.Split(string(content), "\n")
 
@@ -111,37 +164,96 @@ func CreateCrossplaneObject(config Config) {
 			platformpackage.Content.WriteString(fmt.Sprintf("                %s\n", line))
 		}
 		// Convert the content to a string and pass it to the template
+		var target, targetPath string
 		if strings.Contains(platformpackage.Kind, "CustomResourceDefinition") {
+			target, targetPath = SplitTargetCRD, outputDir+"/"+platformpackage.Name+"-crd.yaml"
 			err = temp.Execute(crdFile, platformpackage)
 		} else if strings.Contains(platformpackage.Kind, "Secret") {
+			target, targetPath = SplitTargetSecret, outputDir+"/"+platformpackage.Name+"-secret.yaml"
 			err = temp.Execute(secretFile, platformpackage)
 		} else {
+			target, targetPath = SplitTargetObject, outputDir+"/"+platformpackage.Name+"-object.yaml"
 			err = temp.Execute(objectFile, platformpackage)
 		}
 		if err != nil {
-			log.Fatalln(err)
+			closeOutputs()
+			return fail(err)
 		}
+		if !seenTargets[target] {
+			seenTargets[target] = true
+			progress.SplitTarget(SplitTargetEvent{Target: target})
+		}
+		progress.ManifestProcessed(ManifestProcessedEvent{
+			Kind:         platformpackage.Kind,
+			Path:         targetPath,
+			BytesWritten: platformpackage.Content.Len(),
+		})
 		// Clear the buffer
 		platformpackage.Content.Reset()
 	}
-	removeEmptyLines("output/" + platformpackage.Name + "-object.yaml")
-	removeEmptyLines("output/" + platformpackage.Name + "-crd.yaml")
-	removeEmptyLines("output/" + platformpackage.Name + "-secret.yaml")
+
+	// Close the output files before removeEmptyLines reads them back:
+	// with an in-memory FS, writes aren't visible to other reads on the
+	// same path until Close flushes them.
+	if err := objectFile.Close(); err != nil {
+		return fail(err)
+	}
+	if err := crdFile.Close(); err != nil {
+		return fail(err)
+	}
+	if err := secretFile.Close(); err != nil {
+		return fail(err)
+	}
+
+	if err := removeEmptyLines(fsys, outputDir+"/"+platformpackage.Name+"-object.yaml"); err != nil {
+		return fail(err)
+	}
+	if err := removeEmptyLines(fsys, outputDir+"/"+platformpackage.Name+"-crd.yaml"); err != nil {
+		return fail(err)
+	}
+	if err := removeEmptyLines(fsys, outputDir+"/"+platformpackage.Name+"-secret.yaml"); err != nil {
+		return fail(err)
+	}
+
+	progress.PackageCompleted(PackageCompletedEvent{Duration: time.Since(started)})
+	return nil
 }
 
-// CreatePackage reads the output of the SplitYAML function and writes it to a file
-func CreatePackage(composition_name string, content string) {
+// CreatePackage reads the output of the SplitYAML function and writes it to
+// a file. Progress is reported through config.Progress as it goes; the
+// caller decides whether a returned error is fatal.
+func CreatePackage(config Config, content string) error {
+	progress := config.progress()
+	started := time.Now()
+
+	composition_name := config.Name
 	platformpackage := new(platformpackage)
 	platformpackage.Name = composition_name
-	outfile, err := os.OpenFile("packages/"+composition_name+"-packages.yaml", os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	progress.PackageStarted(PackageStartedEvent{Name: composition_name})
+
+	fail := func(err error) error {
+		progress.Error(ErrorEvent{Err: err})
+		return err
+	}
+
+	fsys := config.fs()
+	if env, ok := config.ActiveEnvironment(); ok {
+		values, err := env.LoadValues(fsys)
+		if err != nil {
+			return fail(err)
+		}
+		platformpackage.Values = values
+	}
+	packagePath := config.packagesDir() + "/" + composition_name + "-packages.yaml"
+	outfile, err := fsys.Create(packagePath)
 	if err != nil {
-		log.Fatalln(err)
+		return fail(err)
 	}
-	defer outfile.Close()
 	// read ebedded filesystem file header.templ and echo into outfile
 	err = htemp.Execute(outfile, platformpackage)
 	if err != nil {
-		log.Fatalln(err)
+		outfile.Close()
+		return fail(err)
 	}
 	lines := strings.Split(string(content), "\n")
 
@@ -149,19 +261,38 @@ func CreatePackage(composition_name string, content string) {
 	contentToAppend := strings.Join(lines, "\n")
 	_, err = io.WriteString(outfile, contentToAppend)
 	if err != nil {
-		log.Fatalln(err)
+		outfile.Close()
+		return fail(err)
 	}
+	progress.ManifestProcessed(ManifestProcessedEvent{
+		Kind:         "package",
+		Path:         packagePath,
+		BytesWritten: len(contentToAppend),
+	})
 	// Execute the footer template
 	err = ftemp.Execute(outfile, platformpackage)
 	if err != nil {
-		log.Fatalln(err)
+		outfile.Close()
+		return fail(err)
 	}
-	removeEmptyLines("packages/" + composition_name + "-packages.yaml")
+
+	// Close the output file before removeEmptyLines reads it back: with
+	// an in-memory FS, writes aren't visible to other reads on the same
+	// path until Close flushes them.
+	if err := outfile.Close(); err != nil {
+		return fail(err)
+	}
+	if err := removeEmptyLines(fsys, packagePath); err != nil {
+		return fail(err)
+	}
+
+	progress.PackageCompleted(PackageCompletedEvent{Duration: time.Since(started)})
+	return nil
 }
 
-func removeEmptyLines(filename string) error {
+func removeEmptyLines(fsys FS, filename string) error {
 	// Read the file
-	data, err := os.ReadFile(filename)
+	data, err := fsys.ReadFile(filename)
 	if err != nil {
 		return err
 	}
@@ -171,7 +302,7 @@ func removeEmptyLines(filename string) error {
 	result := re.ReplaceAllString(string(data), "")
 
 	// Write the result back to the file
-	err = os.WriteFile(filename, []byte(result), os.ModePerm)
+	err = fsys.WriteFile(filename, []byte(result), os.ModePerm)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateCrossplaneObject(t *testing.T) {
+	tests := []struct {
+		name          string
+		manifests     map[string]string
+		wantObjectHas []string
+		wantCrdHas    []string
+		wantSecretHas []string
+		wantAllEmpty  bool
+	}{
+		{
+			name: "object, crd and secret manifests are routed to separate files",
+			manifests: map[string]string{
+				"Deployment_app.yaml":              "kind: Deployment\nmetadata:\n  name: app\n",
+				"CustomResourceDefinition_xr.yaml": "kind: CustomResourceDefinition\nmetadata:\n  name: xr\n",
+				"Secret_creds.yaml":                "kind: Secret\nmetadata:\n  name: creds\n",
+			},
+			wantObjectHas: []string{"kind: Deployment", "name: app"},
+			wantCrdHas:    []string{"kind: CustomResourceDefinition", "name: xr"},
+			wantSecretHas: []string{"kind: Secret", "name: creds"},
+		},
+		{
+			name: "helm hook manifests are skipped",
+			manifests: map[string]string{
+				"Job_hook.yaml": "kind: Job\nmetadata:\n  name: hook\n  annotations:\n    helm.sh/hook: pre-install\n",
+			},
+			wantAllEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemFS()
+			for filename, content := range tt.manifests {
+				if err := mem.WriteFile("working/pkg/"+filename, []byte(content), 0644); err != nil {
+					t.Fatalf("seeding manifest %s: %v", filename, err)
+				}
+			}
+
+			if err := CreateCrossplaneObject(Config{Name: "pkg", FS: mem}); err != nil {
+				t.Fatalf("CreateCrossplaneObject: %v", err)
+			}
+
+			object, _ := mem.ReadFile("output/pkg-object.yaml")
+			crd, _ := mem.ReadFile("output/pkg-crd.yaml")
+			secret, _ := mem.ReadFile("output/pkg-secret.yaml")
+
+			if tt.wantAllEmpty {
+				for label, got := range map[string][]byte{"object": object, "crd": crd, "secret": secret} {
+					if strings.Contains(string(got), "kind:") {
+						t.Errorf("%s file should not contain any manifest, got %q", label, got)
+					}
+				}
+				return
+			}
+
+			assertContainsAll(t, "object", string(object), tt.wantObjectHas)
+			assertContainsAll(t, "crd", string(crd), tt.wantCrdHas)
+			assertContainsAll(t, "secret", string(secret), tt.wantSecretHas)
+		})
+	}
+}
+
+func assertContainsAll(t *testing.T, label, got string, want []string) {
+	t.Helper()
+	for _, substr := range want {
+		if !strings.Contains(got, substr) {
+			t.Errorf("%s file %q does not contain %q", label, got, substr)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+// Config describes a single platform package to generate.
+type Config struct {
+	// Name identifies the package: manifests are read from
+	// <WorkingDir>/<Name>/, and the files written under OutputDir and
+	// PackagesDir are named <Name>-*.yaml.
+	Name string
+
+	// Environments maps an environment name (e.g. "default",
+	// "production") to the values files layered to build that
+	// environment's values tree.
+	Environments map[string]Environment
+
+	// Environment selects which entry in Environments is active for this
+	// run, set from the --environment CLI flag. Defaults to "default"
+	// when empty.
+	Environment string
+
+	// FS is the filesystem CreateCrossplaneObject and CreatePackage read
+	// manifests from and write output to. Defaults to OSFS{} when nil,
+	// so existing callers keep touching the real disk unchanged; tests
+	// can inject a MemFS instead.
+	FS FS
+
+	// WorkingDir, OutputDir and PackagesDir are the roots manifests are
+	// read from and packages/output are written to. They default to
+	// "working", "output" and "packages" respectively, matching the
+	// historical hard-coded paths.
+	WorkingDir  string
+	OutputDir   string
+	PackagesDir string
+
+	// Progress receives structured events as CreateCrossplaneObject and
+	// CreatePackage run. Defaults to a no-op writer when nil.
+	Progress ProgressWriter
+
+	// CRDGen, when set, lets GenerateCRDPackage produce this package's
+	// XRD, example XR and composition files from a Go struct instead of
+	// requiring the CRD YAML to be hand-authored under working/<name>/.
+	CRDGen *CRDGenConfig
+}
+
+func (c Config) progress() ProgressWriter {
+	if c.Progress != nil {
+		return c.Progress
+	}
+	return noopProgressWriter{}
+}
+
+func (c Config) fs() FS {
+	if c.FS != nil {
+		return c.FS
+	}
+	return OSFS{}
+}
+
+func (c Config) workingDir() string {
+	if c.WorkingDir != "" {
+		return c.WorkingDir
+	}
+	return "working"
+}
+
+func (c Config) outputDir() string {
+	if c.OutputDir != "" {
+		return c.OutputDir
+	}
+	return "output"
+}
+
+func (c Config) packagesDir() string {
+	if c.PackagesDir != "" {
+		return c.PackagesDir
+	}
+	return "packages"
+}
+
+// ActiveEnvironment returns the Environment selected by config.Environment,
+// falling back to "default" when config.Environment is empty. The second
+// return value is false when no matching environment is configured.
+func (c Config) ActiveEnvironment() (Environment, bool) {
+	name := c.Environment
+	if name == "" {
+		name = "default"
+	}
+	env, ok := c.Environments[name]
+	return env, ok
+}
@@ -0,0 +1,433 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"gopkg.in/yaml.v3"
+)
+
+// CRDGenConfig describes one Go type to turn into a Crossplane
+// CompositeResourceDefinition (XRD).
+type CRDGenConfig struct {
+	// PackagePattern is a go/packages load pattern (e.g.
+	// "./apis/database/v1alpha1") containing TypeName.
+	PackagePattern string
+	// TypeName is the exported Go struct whose fields become the XRD's
+	// OpenAPI v3 schema. Its doc comment must carry a
+	// "+crossplane:xrd:claim=<ClaimName>" marker.
+	TypeName string
+	// Group and Version are the XRD's spec.group and the single
+	// served+referenceable version name (e.g. "v1alpha1").
+	Group   string
+	Version string
+}
+
+// openAPISchema is a reduced OpenAPI v3 schema, covering the subset of
+// kubebuilder markers CRDGen understands.
+type openAPISchema struct {
+	Type        string                    `yaml:"type,omitempty"`
+	Description string                    `yaml:"description,omitempty"`
+	Properties  map[string]*openAPISchema `yaml:"properties,omitempty"`
+	Required    []string                  `yaml:"required,omitempty"`
+	Enum        []string                  `yaml:"enum,omitempty"`
+	Default     interface{}               `yaml:"default,omitempty"`
+	Pattern     string                    `yaml:"pattern,omitempty"`
+	Minimum     *float64                  `yaml:"minimum,omitempty"`
+	Maximum     *float64                  `yaml:"maximum,omitempty"`
+	Items       *openAPISchema            `yaml:"items,omitempty"`
+}
+
+// compositeResourceDefinition mirrors the fields of a Crossplane
+// apiextensions.crossplane.io/v1 CompositeResourceDefinition that CRDGen
+// populates; fields it doesn't set are omitted rather than zero-valued.
+type compositeResourceDefinition struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   map[string]interface{} `yaml:"metadata"`
+	Spec       xrdSpec                `yaml:"spec"`
+}
+
+type xrdSpec struct {
+	Group      string       `yaml:"group"`
+	Names      xrdNames     `yaml:"names"`
+	ClaimNames *xrdNames    `yaml:"claimNames,omitempty"`
+	Connection []string     `yaml:"connectionSecretKeys,omitempty"`
+	Versions   []xrdVersion `yaml:"versions"`
+}
+
+type xrdNames struct {
+	Kind     string `yaml:"kind"`
+	Plural   string `yaml:"plural"`
+	Singular string `yaml:"singular,omitempty"`
+}
+
+type xrdVersion struct {
+	Name                  string        `yaml:"name"`
+	Served                bool          `yaml:"served"`
+	Referenceable         bool          `yaml:"referenceable"`
+	Schema                xrdVersionDoc `yaml:"schema"`
+	AdditionalPrinterCols []printColumn `yaml:"additionalPrinterColumns,omitempty"`
+}
+
+type xrdVersionDoc struct {
+	OpenAPIV3Schema *openAPISchema `yaml:"openAPIV3Schema"`
+}
+
+// printColumn mirrors the subset of a CRD version's
+// additionalPrinterColumns entry that +kubebuilder:printcolumn: markers
+// populate: the column's header, its OpenAPI type, and the JSONPath into
+// the resource it reads from.
+type printColumn struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	JSONPath string `yaml:"jsonPath"`
+}
+
+var crossplaneXRDMarker = regexp.MustCompile(`\+crossplane:xrd:claim=(\S+)`)
+var crossplaneXRDSecretKeysMarker = regexp.MustCompile(`\+crossplane:xrd:connectionSecretKeys=(\S+)`)
+var kubebuilderMinimum = regexp.MustCompile(`\+kubebuilder:validation:Minimum=(\S+)`)
+var kubebuilderMaximum = regexp.MustCompile(`\+kubebuilder:validation:Maximum=(\S+)`)
+var kubebuilderPattern = regexp.MustCompile(`(?m)\+kubebuilder:validation:Pattern=(.+)$`)
+var kubebuilderEnum = regexp.MustCompile(`(?m)\+kubebuilder:validation:Enum=(.+)$`)
+var kubebuilderRequired = regexp.MustCompile(`\+kubebuilder:validation:Required`)
+var kubebuilderDefault = regexp.MustCompile(`(?m)\+kubebuilder:default=(.+)$`)
+var kubebuilderPrintColumn = regexp.MustCompile(`(?m)\+kubebuilder:printcolumn:(.+)$`)
+
+// GenerateXRD loads cfg.PackagePattern with go/packages, finds cfg.TypeName,
+// and emits the corresponding CompositeResourceDefinition YAML with an
+// OpenAPI v3 schema built from the struct's fields, their json tags and
+// their +kubebuilder marker comments.
+func GenerateXRD(cfg CRDGenConfig) ([]byte, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedSyntax | packages.NeedTypes,
+	}, cfg.PackagePattern)
+	if err != nil {
+		return nil, fmt.Errorf("loading package %s: %w", cfg.PackagePattern, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("package %s not found", cfg.PackagePattern)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, fmt.Errorf("loading package %s: %v", cfg.PackagePattern, pkgs[0].Errors[0])
+	}
+
+	typeSpec, doc, err := findStruct(pkgs[0], cfg.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	claimMatch := crossplaneXRDMarker.FindStringSubmatch(doc)
+	if claimMatch == nil {
+		return nil, fmt.Errorf("%s is missing a +crossplane:xrd:claim= marker", cfg.TypeName)
+	}
+	claimName := claimMatch[1]
+
+	var connectionSecretKeys []string
+	if m := crossplaneXRDSecretKeysMarker.FindStringSubmatch(doc); m != nil {
+		connectionSecretKeys = strings.Split(m[1], ";")
+	}
+
+	printColumns, err := printColumnsFromDoc(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing printcolumn markers for %s: %w", cfg.TypeName, err)
+	}
+
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct", cfg.TypeName)
+	}
+	schema, err := schemaForStruct(structType)
+	if err != nil {
+		return nil, fmt.Errorf("building schema for %s: %w", cfg.TypeName, err)
+	}
+
+	xrd := compositeResourceDefinition{
+		APIVersion: "apiextensions.crossplane.io/v1",
+		Kind:       "CompositeResourceDefinition",
+		Metadata: map[string]interface{}{
+			"name": strings.ToLower(cfg.TypeName) + "s." + cfg.Group,
+		},
+		Spec: xrdSpec{
+			Group: cfg.Group,
+			Names: xrdNames{
+				Kind:   cfg.TypeName,
+				Plural: strings.ToLower(cfg.TypeName) + "s",
+			},
+			ClaimNames: &xrdNames{
+				Kind:   claimName,
+				Plural: strings.ToLower(claimName) + "s",
+			},
+			Connection: connectionSecretKeys,
+			Versions: []xrdVersion{{
+				Name:                  cfg.Version,
+				Served:                true,
+				Referenceable:         true,
+				Schema:                xrdVersionDoc{OpenAPIV3Schema: schema},
+				AdditionalPrinterCols: printColumns,
+			}},
+		},
+	}
+
+	return yaml.Marshal(xrd)
+}
+
+// printColumnsFromDoc parses every +kubebuilder:printcolumn: marker in doc
+// into a printColumn. Each marker is a comma-separated list of key=value
+// pairs; name, type and JSONPath are the keys CRDGen understands.
+func printColumnsFromDoc(doc string) ([]printColumn, error) {
+	matches := kubebuilderPrintColumn.FindAllStringSubmatch(doc, -1)
+	var columns []printColumn
+	for _, m := range matches {
+		col := printColumn{}
+		for _, pair := range strings.Split(m[1], ",") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("malformed printcolumn field %q", pair)
+			}
+			value := strings.Trim(kv[1], `"`)
+			switch kv[0] {
+			case "name":
+				col.Name = value
+			case "type":
+				col.Type = value
+			case "JSONPath":
+				col.JSONPath = value
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// GenerateCRDPackage uses config.CRDGen to produce the XRD, a matching
+// example XR and a composition stub in one pass, writing all three under
+// config.outputDir() alongside the files CreateCrossplaneObject writes.
+// This removes the need to hand-author the XRD YAML that
+// CreateCrossplaneObject otherwise just copies through.
+func GenerateCRDPackage(config Config) error {
+	if config.CRDGen == nil {
+		return fmt.Errorf("crdgen: Config.CRDGen is not set")
+	}
+
+	xrdYAML, err := GenerateXRD(*config.CRDGen)
+	if err != nil {
+		return err
+	}
+
+	fsys := config.fs()
+	outputDir := config.outputDir()
+	name := config.Name
+
+	if err := fsys.WriteFile(outputDir+"/"+name+"-xrd.yaml", xrdYAML, 0644); err != nil {
+		return fmt.Errorf("writing xrd: %w", err)
+	}
+
+	exampleXR := map[string]interface{}{
+		"apiVersion": config.CRDGen.Group + "/" + config.CRDGen.Version,
+		"kind":       config.CRDGen.TypeName,
+		"metadata":   map[string]interface{}{"name": "example-" + strings.ToLower(config.CRDGen.TypeName)},
+		"spec":       map[string]interface{}{},
+	}
+	exampleYAML, err := yaml.Marshal(exampleXR)
+	if err != nil {
+		return fmt.Errorf("marshalling example XR: %w", err)
+	}
+	if err := fsys.WriteFile(outputDir+"/"+name+"-example.yaml", exampleYAML, 0644); err != nil {
+		return fmt.Errorf("writing example XR: %w", err)
+	}
+
+	composition := map[string]interface{}{
+		"apiVersion": "apiextensions.crossplane.io/v1",
+		"kind":       "Composition",
+		"metadata":   map[string]interface{}{"name": strings.ToLower(config.CRDGen.TypeName) + "-composition"},
+		"spec": map[string]interface{}{
+			"compositeTypeRef": map[string]interface{}{
+				"apiVersion": config.CRDGen.Group + "/" + config.CRDGen.Version,
+				"kind":       config.CRDGen.TypeName,
+			},
+		},
+	}
+	compositionYAML, err := yaml.Marshal(composition)
+	if err != nil {
+		return fmt.Errorf("marshalling composition: %w", err)
+	}
+	if err := fsys.WriteFile(outputDir+"/"+name+"-composition.yaml", compositionYAML, 0644); err != nil {
+		return fmt.Errorf("writing composition: %w", err)
+	}
+
+	return nil
+}
+
+// findStruct returns the *ast.TypeSpec and doc comment for a top-level
+// struct type named name in pkg.
+func findStruct(pkg *packages.Package, name string) (*ast.TypeSpec, string, error) {
+	for _, file := range pkg.Syntax {
+		pkgDoc := doc.New(&ast.Package{Name: pkg.Name, Files: map[string]*ast.File{"": file}}, "", doc.AllDecls)
+		for _, t := range pkgDoc.Types {
+			if t.Name != name {
+				continue
+			}
+			for _, spec := range t.Decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+					return ts, t.Doc, nil
+				}
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("type %s not found in package", name)
+}
+
+// schemaForStruct walks a struct's fields, turning each into an OpenAPI v3
+// schema property keyed by its json tag (falling back to the Go field
+// name), applying the kubebuilder markers found on the field's doc
+// comment.
+func schemaForStruct(structType *ast.StructType) (*openAPISchema, error) {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			continue // embedded field; not supported
+		}
+		name := field.Names[0].Name
+		jsonName := name
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				if jsonTag := reflect.StructTag(tag).Get("json"); jsonTag != "" {
+					if tagName := strings.Split(jsonTag, ",")[0]; tagName != "" {
+						jsonName = tagName
+					}
+				}
+			}
+		}
+
+		fieldDoc := field.Doc.Text()
+		prop := schemaForExpr(field.Type)
+		prop.Description = strings.TrimSpace(stripMarkers(fieldDoc))
+		if m := kubebuilderPattern.FindStringSubmatch(fieldDoc); m != nil {
+			prop.Pattern = m[1]
+		}
+		if m := kubebuilderEnum.FindStringSubmatch(fieldDoc); m != nil {
+			prop.Enum = strings.Split(m[1], ";")
+		}
+		if m := kubebuilderDefault.FindStringSubmatch(fieldDoc); m != nil {
+			prop.Default = coerceDefault(prop.Type, m[1])
+		}
+		if m := kubebuilderMinimum.FindStringSubmatch(fieldDoc); m != nil {
+			if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+				prop.Minimum = &v
+			}
+		}
+		if m := kubebuilderMaximum.FindStringSubmatch(fieldDoc); m != nil {
+			if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+				prop.Maximum = &v
+			}
+		}
+
+		schema.Properties[jsonName] = prop
+		if kubebuilderRequired.MatchString(fieldDoc) {
+			schema.Required = append(schema.Required, jsonName)
+		}
+	}
+	return schema, nil
+}
+
+// schemaForExpr builds the OpenAPI schema for a single Go type expression,
+// recursing into array/slice element types so a []string field (and a
+// [][]string one) carries the Items schema Kubernetes structural-schema
+// validation requires alongside "type: array".
+func schemaForExpr(expr ast.Expr) *openAPISchema {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return schemaForExpr(star.X)
+	}
+	if arr, ok := expr.(*ast.ArrayType); ok {
+		return &openAPISchema{Type: "array", Items: schemaForExpr(arr.Elt)}
+	}
+	return &openAPISchema{Type: goTypeToOpenAPIType(expr)}
+}
+
+// goTypeToOpenAPIType maps the common Go scalar kinds to their OpenAPI v3
+// equivalent, unwrapping the pointer form kubebuilder-style APIs use for
+// optional fields and reporting slices and arrays as "array" (their element
+// type is not inspected here); anything else is left as "object" since
+// a full mapping would need the type-checker's resolved types rather than
+// raw syntax.
+func goTypeToOpenAPIType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goTypeToOpenAPIType(t.X)
+	case *ast.ArrayType:
+		return "array"
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "int", "int32", "int64", "uint", "uint32", "uint64":
+			return "integer"
+		case "float32", "float64":
+			return "number"
+		default:
+			return "object"
+		}
+	default:
+		return "object"
+	}
+}
+
+// coerceDefault parses a +kubebuilder:default= marker's raw text into the
+// Go value whose YAML encoding matches the field's OpenAPI type, so e.g. a
+// boolean field's default is emitted as `true` rather than the string
+// "true".
+func coerceDefault(openAPIType, raw string) interface{} {
+	switch openAPIType {
+	case "boolean":
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	case "integer":
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// stripMarkers removes +kubebuilder:/+crossplane: marker lines from a doc
+// comment so they don't leak into the generated schema's description.
+func stripMarkers(docComment string) string {
+	lines := strings.Split(docComment, "\n")
+	var kept []string
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "+") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,80 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"go/parser"
+	"testing"
+)
+
+func TestSchemaForExpr(t *testing.T) {
+	tests := []struct {
+		name      string
+		typeExpr  string
+		wantType  string
+		wantItems string // empty means Items should be nil
+	}{
+		{name: "string", typeExpr: "string", wantType: "string"},
+		{name: "pointer to bool is unwrapped", typeExpr: "*bool", wantType: "boolean"},
+		{name: "slice of strings gets an items schema", typeExpr: "[]string", wantType: "array", wantItems: "string"},
+		{name: "slice of ints gets an items schema", typeExpr: "[]int", wantType: "array", wantItems: "integer"},
+		{name: "pointer to slice is unwrapped", typeExpr: "*[]string", wantType: "array", wantItems: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.typeExpr)
+			if err != nil {
+				t.Fatalf("parsing type expr %q: %v", tt.typeExpr, err)
+			}
+			schema := schemaForExpr(expr)
+			if schema.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", schema.Type, tt.wantType)
+			}
+			if tt.wantItems == "" {
+				if schema.Items != nil {
+					t.Errorf("Items = %+v, want nil", schema.Items)
+				}
+				return
+			}
+			if schema.Items == nil {
+				t.Fatalf("Items = nil, want Type %q", tt.wantItems)
+			}
+			if schema.Items.Type != tt.wantItems {
+				t.Errorf("Items.Type = %q, want %q", schema.Items.Type, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestPrintColumnsFromDoc(t *testing.T) {
+	doc := "Foo is a thing.\n" +
+		`+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready"` + "\n"
+
+	cols, err := printColumnsFromDoc(doc)
+	if err != nil {
+		t.Fatalf("printColumnsFromDoc: %v", err)
+	}
+	if len(cols) != 1 {
+		t.Fatalf("got %d columns, want 1", len(cols))
+	}
+
+	want := printColumn{Name: "Ready", Type: "string", JSONPath: ".status.ready"}
+	if cols[0] != want {
+		t.Errorf("got %+v, want %+v", cols[0], want)
+	}
+}
@@ -0,0 +1,101 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment is a named, helmfile-style overlay: an ordered list of values
+// files that are parsed as YAML (after template execution, for
+// *.yaml.gotmpl files) and merged on top of one another to build the values
+// tree exposed to templates as .Values.
+type Environment struct {
+	ValuesFiles []string
+}
+
+// environmentTemplateData is the context exposed to a *.yaml.gotmpl values
+// file: .Environment.Values holds the values merged from every earlier file
+// in the environment, so a later file can reference or override them.
+type environmentTemplateData struct {
+	Environment struct {
+		Values map[string]interface{}
+	}
+}
+
+// LoadValues reads each of the environment's values files through fsys in
+// order, merging each one on top of the result of the previous files so
+// later files can reference and override values defined by earlier ones.
+// *.yaml.gotmpl files are executed as Go templates before being parsed as
+// YAML, with .Environment.Values set to the values merged so far.
+func (e Environment) LoadValues(fsys FS) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, path := range e.ValuesFiles {
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading values file %s: %w", path, err)
+		}
+		if strings.HasSuffix(path, ".gotmpl") {
+			if data, err = renderValuesTemplate(path, data, values); err != nil {
+				return nil, err
+			}
+		}
+		var layer map[string]interface{}
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("parsing values file %s: %w", path, err)
+		}
+		values = mergeValues(values, layer)
+	}
+	return values, nil
+}
+
+// renderValuesTemplate executes a values file's *.yaml.gotmpl contents as a
+// Go template, exposing valuesSoFar as .Environment.Values.
+func renderValuesTemplate(path string, data []byte, valuesSoFar map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(path).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing values template %s: %w", path, err)
+	}
+	var ctx environmentTemplateData
+	ctx.Environment.Values = valuesSoFar
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, ctx); err != nil {
+		return nil, fmt.Errorf("executing values template %s: %w", path, err)
+	}
+	return rendered.Bytes(), nil
+}
+
+// mergeValues deep-merges src into dst, recursing into nested maps so a
+// values file only needs to specify the keys it overrides, and overwriting
+// everything else (scalars, slices) outright.
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,156 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory FS implementation intended for tests. It stores
+// file contents in a flat map keyed by a cleaned, slash-separated path and
+// synthesizes directory listings from path prefixes.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string][]byte{}}
+}
+
+func (m *MemFS) clean(name string) string {
+	return path.Clean(strings.ReplaceAll(name, string(os.PathSeparator), "/"))
+}
+
+func (m *MemFS) Open(name string) (io.ReadCloser, error) {
+	data, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[m.clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	return out, nil
+}
+
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	return &memFile{fs: m, name: m.clean(name)}, nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := make([]byte, len(data))
+	copy(stored, data)
+	m.files[m.clean(name)] = stored
+	return nil
+}
+
+func (m *MemFS) MkdirAll(dirPath string, perm os.FileMode) error {
+	// Directories are implicit in MemFS: any file written under dirPath
+	// makes it show up in ReadDir, so there is nothing to persist here.
+	return nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := m.clean(name) + "/"
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for p, data := range m.files {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(p, prefix)
+		if idx := strings.Index(rest, "/"); idx >= 0 {
+			// Entry belongs to a nested directory; surface the
+			// directory itself at most once.
+			dirName := rest[:idx]
+			if seen[dirName] {
+				continue
+			}
+			seen[dirName] = true
+			entries = append(entries, memDirEntry{name: dirName, isDir: true})
+			continue
+		}
+		if seen[rest] {
+			continue
+		}
+		seen[rest] = true
+		entries = append(entries, memDirEntry{name: rest, size: int64(len(data))})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type memDirEntry struct {
+	name  string
+	isDir bool
+	size  int64
+}
+
+func (e memDirEntry) Name() string { return e.name }
+func (e memDirEntry) IsDir() bool  { return e.isDir }
+func (e memDirEntry) Type() fs.FileMode {
+	if e.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+func (e memDirEntry) Info() (fs.FileInfo, error) { return memFileInfo(e), nil }
+
+type memFileInfo memDirEntry
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return memDirEntry(i).Type() }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return i.isDir }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+// memFile buffers writes and flushes them to the owning MemFS on Close, so
+// partially written files never become visible to concurrent readers.
+type memFile struct {
+	fs   *MemFS
+	name string
+	buf  bytes.Buffer
+}
+
+func (f *memFile) Write(p []byte) (int, error) { return f.buf.Write(p) }
+
+func (f *memFile) Close() error {
+	return f.fs.WriteFile(f.name, f.buf.Bytes(), 0644)
+}
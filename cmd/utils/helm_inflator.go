@@ -0,0 +1,168 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmChartInflator renders a Helm chart with `helm template` and splits the
+// resulting manifest into the same per-kind file layout that
+// CreateCrossplaneObject expects under working/<name>/, so a chart can be
+// turned into a Crossplane package in one step instead of requiring the
+// caller to shell out to helm and pre-split the output themselves.
+type HelmChartInflator struct {
+	// HelmBin is the path to the helm binary. Defaults to "helm" on PATH.
+	HelmBin string
+	// Chart is the chart reference: a repo-hosted "repo/chart", an OCI
+	// reference ("oci://..."), or a local filesystem path.
+	Chart string
+	// Version pins the chart version. Ignored for local chart paths.
+	Version string
+	// ReleaseName and Namespace are passed through to `helm template`.
+	ReleaseName string
+	Namespace   string
+	// ValuesFiles is passed to helm as repeated -f flags, in order.
+	ValuesFiles []string
+	// Values is passed to helm as --set key=value pairs, applied after
+	// ValuesFiles.
+	Values map[string]string
+	// RegistryConfigFile, if set, is exported as HELM_REGISTRY_CONFIG so
+	// `helm template` can authenticate against private OCI registries.
+	RegistryConfigFile string
+}
+
+// Inflate runs `helm template` for the configured chart in a scratch temp
+// directory, splits the rendered manifests into working/<name>/ using the
+// <Kind>_<Name>.yaml convention CreateCrossplaneObject derives each
+// manifest's kind from (skipping helm.sh/hook resources), and hands off to
+// CreateCrossplaneObject.
+func (h *HelmChartInflator) Inflate(config Config) error {
+	scratch, err := os.MkdirTemp("", "cluster-forge-helm-*")
+	if err != nil {
+		return fmt.Errorf("creating scratch dir: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	rendered, err := h.template(scratch)
+	if err != nil {
+		return fmt.Errorf("running helm template: %w", err)
+	}
+
+	fsys := config.fs()
+	workingDir := config.workingDir() + "/" + config.Name
+	if err := fsys.MkdirAll(workingDir, 0755); err != nil {
+		return fmt.Errorf("creating working dir: %w", err)
+	}
+
+	if err := splitHelmOutput(fsys, rendered, workingDir); err != nil {
+		return err
+	}
+
+	return CreateCrossplaneObject(config)
+}
+
+func (h *HelmChartInflator) helmBin() string {
+	if h.HelmBin != "" {
+		return h.HelmBin
+	}
+	return "helm"
+}
+
+// template shells out to `helm template` and returns the rendered
+// multi-document YAML.
+func (h *HelmChartInflator) template(scratch string) ([]byte, error) {
+	args := []string{"template"}
+	if h.ReleaseName != "" {
+		args = append(args, h.ReleaseName)
+	}
+	args = append(args, h.Chart)
+	if h.Version != "" {
+		args = append(args, "--version", h.Version)
+	}
+	if h.Namespace != "" {
+		args = append(args, "--namespace", h.Namespace)
+	}
+	for _, f := range h.ValuesFiles {
+		args = append(args, "-f", f)
+	}
+	for k, v := range h.Values {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	cmd := exec.Command(h.helmBin(), args...)
+	cmd.Dir = scratch
+	cmd.Env = os.Environ()
+	if h.RegistryConfigFile != "" {
+		cmd.Env = append(cmd.Env, "HELM_REGISTRY_CONFIG="+h.RegistryConfigFile)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// helmManifest is the subset of a rendered Kubernetes manifest
+// splitHelmOutput needs to name its output file.
+type helmManifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+}
+
+// splitHelmOutput splits a multi-document helm template output into
+// individual files named <Kind>_<Name>.yaml under dir, skipping any
+// document that carries a helm.sh/hook annotation or has no kind/
+// metadata.name (e.g. one relying on generateName).
+func splitHelmOutput(fsys FS, rendered []byte, dir string) error {
+	docs := strings.Split(string(rendered), "\n---\n")
+	for _, doc := range docs {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+		if strings.Contains(doc, "helm.sh/hook") {
+			continue
+		}
+
+		var manifest helmManifest
+		if err := yaml.Unmarshal([]byte(doc), &manifest); err != nil {
+			return fmt.Errorf("parsing rendered manifest: %w", err)
+		}
+		if manifest.Kind == "" || manifest.Metadata.Name == "" {
+			continue
+		}
+
+		filename := fmt.Sprintf("%s_%s.yaml", manifest.Kind, manifest.Metadata.Name)
+		path := dir + "/" + filename
+		if err := fsys.WriteFile(path, []byte(doc+"\n"), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitHelmOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		rendered    string
+		wantFiles   map[string]string
+		wantSkipped []string
+	}{
+		{
+			name: "documents are split by kind and metadata.name",
+			rendered: "kind: Deployment\nmetadata:\n  name: app\n" +
+				"\n---\n" +
+				"kind: Service\nmetadata:\n  name: app\n",
+			wantFiles: map[string]string{
+				"Deployment_app.yaml": "kind: Deployment",
+				"Service_app.yaml":    "kind: Service",
+			},
+		},
+		{
+			name:        "helm hook documents are skipped",
+			rendered:    "kind: Job\nmetadata:\n  name: hook\n  annotations:\n    helm.sh/hook: pre-install\n",
+			wantSkipped: []string{"Job_hook.yaml"},
+		},
+		{
+			name:        "documents without metadata.name are skipped",
+			rendered:    "kind: Deployment\nmetadata:\n  generateName: app-\n",
+			wantSkipped: []string{"Deployment_.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemFS()
+			if err := splitHelmOutput(mem, []byte(tt.rendered), "working/pkg"); err != nil {
+				t.Fatalf("splitHelmOutput: %v", err)
+			}
+
+			for filename, substr := range tt.wantFiles {
+				content, err := mem.ReadFile("working/pkg/" + filename)
+				if err != nil {
+					t.Fatalf("reading %s: %v", filename, err)
+				}
+				if !strings.Contains(string(content), substr) {
+					t.Errorf("%s = %q, want substring %q", filename, content, substr)
+				}
+			}
+			for _, filename := range tt.wantSkipped {
+				if _, err := mem.ReadFile("working/pkg/" + filename); err == nil {
+					t.Errorf("%s should not have been written", filename)
+				}
+			}
+		})
+	}
+}
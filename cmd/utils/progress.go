@@ -0,0 +1,236 @@
+/**
+ * Copyright 2024 Advanced Micro Devices, Inc.  All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+**/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Split target names used by SplitTargetEvent.Target, matching the three
+// output files CreateCrossplaneObject writes.
+const (
+	SplitTargetObject = "object"
+	SplitTargetCRD    = "crd"
+	SplitTargetSecret = "secret"
+)
+
+// PackageStartedEvent fires once when CreateCrossplaneObject or
+// CreatePackage begins working on a package.
+type PackageStartedEvent struct {
+	Name string
+}
+
+// ManifestProcessedEvent fires once per manifest written to an output file.
+type ManifestProcessedEvent struct {
+	Kind         string
+	Path         string
+	BytesWritten int
+}
+
+// SplitTargetEvent fires the first time a manifest is routed to a given
+// split target (object, crd or secret) for a package.
+type SplitTargetEvent struct {
+	Target string
+}
+
+// PackageCompletedEvent fires once a package has finished generating
+// successfully; a package that fails gets an ErrorEvent instead.
+type PackageCompletedEvent struct {
+	Duration time.Duration
+}
+
+// ErrorEvent fires whenever CreateCrossplaneObject or CreatePackage would
+// previously have called log.Fatalln; the error is returned to the caller
+// instead, who decides whether it's fatal.
+type ErrorEvent struct {
+	Err error
+}
+
+// ProgressWriter receives structured events while a package is generated,
+// so callers running in CI or a TUI can render progress instead of relying
+// on log output.
+type ProgressWriter interface {
+	PackageStarted(PackageStartedEvent)
+	ManifestProcessed(ManifestProcessedEvent)
+	SplitTarget(SplitTargetEvent)
+	PackageCompleted(PackageCompletedEvent)
+	Error(ErrorEvent)
+}
+
+// noopProgressWriter is used when Config.Progress is nil so call sites
+// never have to nil-check before emitting an event.
+type noopProgressWriter struct{}
+
+func (noopProgressWriter) PackageStarted(PackageStartedEvent)       {}
+func (noopProgressWriter) ManifestProcessed(ManifestProcessedEvent) {}
+func (noopProgressWriter) SplitTarget(SplitTargetEvent)             {}
+func (noopProgressWriter) PackageCompleted(PackageCompletedEvent)   {}
+func (noopProgressWriter) Error(ErrorEvent)                         {}
+
+// TextProgressWriter renders events as plain human-readable log lines.
+type TextProgressWriter struct {
+	Out io.Writer
+}
+
+func NewTextProgressWriter(out io.Writer) *TextProgressWriter {
+	return &TextProgressWriter{Out: out}
+}
+
+func (w *TextProgressWriter) PackageStarted(e PackageStartedEvent) {
+	fmt.Fprintf(w.Out, "==> %s: generating\n", e.Name)
+}
+
+func (w *TextProgressWriter) ManifestProcessed(e ManifestProcessedEvent) {
+	fmt.Fprintf(w.Out, "    %s %s (%d bytes)\n", e.Kind, e.Path, e.BytesWritten)
+}
+
+func (w *TextProgressWriter) SplitTarget(e SplitTargetEvent) {}
+
+func (w *TextProgressWriter) PackageCompleted(e PackageCompletedEvent) {
+	fmt.Fprintf(w.Out, "==> done in %s\n", e.Duration)
+}
+
+func (w *TextProgressWriter) Error(e ErrorEvent) {
+	fmt.Fprintf(w.Out, "!!! %v\n", e.Err)
+}
+
+// JSONProgressWriter renders events as JSON Lines for machine consumption.
+type JSONProgressWriter struct {
+	Out io.Writer
+	mu  sync.Mutex
+}
+
+func NewJSONProgressWriter(out io.Writer) *JSONProgressWriter {
+	return &JSONProgressWriter{Out: out}
+}
+
+func (w *JSONProgressWriter) emit(eventType string, payload interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	line := struct {
+		Type  string      `json:"type"`
+		Event interface{} `json:"event"`
+	}{Type: eventType, Event: payload}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w.Out, string(data))
+}
+
+func (w *JSONProgressWriter) PackageStarted(e PackageStartedEvent) { w.emit("package_started", e) }
+
+func (w *JSONProgressWriter) ManifestProcessed(e ManifestProcessedEvent) {
+	w.emit("manifest_processed", e)
+}
+
+func (w *JSONProgressWriter) SplitTarget(e SplitTargetEvent) { w.emit("split_target", e) }
+
+func (w *JSONProgressWriter) PackageCompleted(e PackageCompletedEvent) {
+	w.emit("package_completed", e)
+}
+
+func (w *JSONProgressWriter) Error(e ErrorEvent) {
+	w.emit("error", map[string]string{"error": e.Err.Error()})
+}
+
+// ConsoleProgressWriter renders a redrawing, per-package summary in the
+// spirit of the buildkit progress UI: a spinner frame followed by running
+// counts of processed manifests per ManifestProcessedEvent.Kind, repainted
+// in place with ANSI cursor-movement escapes rather than appended as a new
+// line per event. SplitTarget events are not shown.
+type ConsoleProgressWriter struct {
+	Out io.Writer
+
+	mu     sync.Mutex
+	frame  int
+	name   string
+	order  []string
+	counts map[string]int
+	// lines is how many lines the previous paint left on screen, so the
+	// next one knows how far back to move the cursor before overwriting.
+	lines int
+}
+
+var consoleSpinnerFrames = []rune{'|', '/', '-', '\\'}
+
+func NewConsoleProgressWriter(out io.Writer) *ConsoleProgressWriter {
+	return &ConsoleProgressWriter{Out: out, counts: map[string]int{}}
+}
+
+func (w *ConsoleProgressWriter) PackageStarted(e PackageStartedEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.name = e.Name
+	w.order = nil
+	w.counts = map[string]int{}
+	w.paint()
+}
+
+func (w *ConsoleProgressWriter) ManifestProcessed(e ManifestProcessedEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.counts[e.Kind]; !ok {
+		w.order = append(w.order, e.Kind)
+	}
+	w.counts[e.Kind]++
+	w.frame++
+	w.paint()
+}
+
+func (w *ConsoleProgressWriter) SplitTarget(e SplitTargetEvent) {}
+
+func (w *ConsoleProgressWriter) PackageCompleted(e PackageCompletedEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.erase()
+	fmt.Fprintf(w.Out, "✓ %s completed in %s\n", w.name, e.Duration)
+	w.lines = 0
+}
+
+func (w *ConsoleProgressWriter) Error(e ErrorEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.erase()
+	fmt.Fprintf(w.Out, "✗ %v\n", e.Err)
+	w.lines = 0
+}
+
+// paint erases whatever the previous call left on screen and redraws the
+// current frame: a spinner line for the package, followed by one running
+// count line per manifest kind seen so far.
+func (w *ConsoleProgressWriter) paint() {
+	w.erase()
+	fmt.Fprintf(w.Out, "%c %s\n", consoleSpinnerFrames[w.frame%len(consoleSpinnerFrames)], w.name)
+	for _, kind := range w.order {
+		fmt.Fprintf(w.Out, "  %s x%d\n", kind, w.counts[kind])
+	}
+	w.lines = 1 + len(w.order)
+}
+
+// erase moves the cursor up over the previous paint's lines and clears
+// each one, so the next paint overwrites it in place instead of scrolling
+// the terminal.
+func (w *ConsoleProgressWriter) erase() {
+	for i := 0; i < w.lines; i++ {
+		fmt.Fprint(w.Out, "\033[1A\033[2K")
+	}
+}